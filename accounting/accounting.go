@@ -8,11 +8,13 @@ package accounting
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
 	"time"
 
+	"github.com/bittorrent/go-btfs/settlement"
 	"github.com/bittorrent/go-btfs/transaction/storage"
 	logging "github.com/ipfs/go-log"
 )
@@ -106,6 +108,11 @@ func (a *Accounting) NotifyPaymentSent(peer string, amount *big.Int, receivedErr
 
 	fmt.Println("NotifyPaymentSent...")
 
+	if errors.Is(receivedError, settlement.ErrPaymentQueued) {
+		log.Infof("accounting: payment to peer %v queued until the chain is reachable", peer)
+		return
+	}
+
 	if receivedError != nil {
 		accountingPeer.lastSettlementFailureTimestamp = a.timeNow().Unix()
 		log.Warnf("accounting: payment failure %v", receivedError)
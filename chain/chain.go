@@ -14,6 +14,7 @@ import (
 	"github.com/bittorrent/go-btfs/settlement"
 	"github.com/bittorrent/go-btfs/settlement/swap"
 	"github.com/bittorrent/go-btfs/settlement/swap/priceoracle"
+	"github.com/bittorrent/go-btfs/settlement/swap/reporting"
 	"github.com/bittorrent/go-btfs/settlement/swap/swapprotocol"
 	"github.com/bittorrent/go-btfs/settlement/swap/vault"
 	"github.com/bittorrent/go-btfs/transaction"
@@ -55,6 +56,7 @@ type SettleInfo struct {
 	CashoutService vault.CashoutService
 	SwapService    *swap.Service
 	OracleService  priceoracle.Service
+	ReportingStore *reporting.Store // nil unless settlement reporting is enabled
 }
 
 // InitChain will initialize the Ethereum backend at the given endpoint and
@@ -169,10 +171,12 @@ func InitSettlement(
 	)
 
 	if err != nil {
-		return nil, errors.New("init swap service error")
+		return nil, fmt.Errorf("init swap service: %w", err)
 	}
 
 	accounting.SetPayFunc(swapService.Pay)
+	// queued payments are resubmitted through accounting, so only start once it can pay
+	swapService.StartOutbox()
 
 	SettleObject = SettleInfo{
 		Factory:        factory,
@@ -329,6 +333,17 @@ func initSwap(
 		cashoutService,
 		accounting,
 	)
+	// queue payments while the chain or the price oracle is unreachable
+	err := swapService.SetOutbox(swap.DefaultOutboxCapacity, swap.DefaultOutboxRetryInterval, func(ctx context.Context) error {
+		if _, err := vaultService.TotalBalance(ctx); err != nil {
+			return err
+		}
+		_, err := priceOracle.GetPrice(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("init swap outbox: %w", err)
+	}
 
 	swapProtocol.SetSwap(swapService)
 	swapprotocol.SwapProtocol = swapProtocol
@@ -0,0 +1,60 @@
+package chain
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bittorrent/go-btfs/settlement/swap/reporting"
+	"github.com/bittorrent/go-btfs/settlement/swap/vault"
+)
+
+// InitReportingStore opens the SQLite reporting database in dataDir and
+// mirrors all cheque and cashout records of settleInfo into it.
+//
+// The cheque records are rebuilt from the node's records on every start.
+// The node drops cheque records after 180 days, so older cheques are not
+// in the database after a restart either. Cashouts are never removed.
+func InitReportingStore(dataDir string, settleInfo *SettleInfo) (*reporting.Store, error) {
+	store, err := reporting.Open(filepath.Join(dataDir, "reporting.db"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = rebuildRecords(store, settleInfo)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("rebuild reporting records: %w", err)
+	}
+
+	vault.SetReporter(store)
+	settleInfo.ReportingStore = store
+
+	return store, nil
+}
+
+func rebuildRecords(store *reporting.Store, settleInfo *SettleInfo) error {
+	receivedAll, err := settleInfo.ChequeStore.ReceivedChequeRecordsAll()
+	if err != nil {
+		return err
+	}
+	received := make([]vault.ChequeRecord, 0)
+	for _, records := range receivedAll {
+		received = append(received, records...)
+	}
+
+	sentAll, err := settleInfo.ChequeStore.SendChequeRecordsAll()
+	if err != nil {
+		return err
+	}
+	sent := make([]vault.ChequeRecord, 0)
+	for _, records := range sentAll {
+		sent = append(sent, records...)
+	}
+
+	cashouts, err := settleInfo.CashoutService.CashoutResults()
+	if err != nil {
+		return err
+	}
+
+	return store.Rebuild(received, sent, cashouts)
+}
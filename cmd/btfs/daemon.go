@@ -86,6 +86,7 @@ const (
 	swarmPortKwd              = "swarm-port"
 	deploymentGasPrice        = "deployment-gasPrice"
 	chainID                   = "chain-id"
+	enableReportingKwd        = "enable-settlement-reporting"
 	// apiAddrKwd    = "address-api"
 	// swarmAddrKwd  = "address-swarm"
 )
@@ -212,6 +213,7 @@ Headers.
 		cmds.StringOption(swarmPortKwd, "Override existing announced swarm address with external port in the format of [WAN:LAN]."),
 		cmds.StringOption(deploymentGasPrice, "gas price in unit to use for deployment and funding."),
 		cmds.StringOption(chainID, "The ID of blockchain to deploy."),
+		cmds.BoolOption(enableReportingKwd, "Mirror cheque and cashout records into a SQLite database for `btfs settlement query`.").WithDefault(false),
 		// TODO: add way to override addresses. tricky part: updating the config if also --init.
 		// cmds.StringOption(apiAddrKwd, "Address for the daemon rpc API (overrides config)"),
 		// cmds.StringOption(swarmAddrKwd, "Address for the swarm socket (overrides config)"),
@@ -439,11 +441,23 @@ func daemonFunc(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment
 	}
 
 	/*settleinfo*/
-	_, err = chain.InitSettlement(context.Background(), statestore, chainInfo, deployGasPrice, chainInfo.ChainID)
+	settleInfo, err := chain.InitSettlement(context.Background(), statestore, chainInfo, deployGasPrice, chainInfo.ChainID)
 	if err != nil {
 		fmt.Println("init settlement err: ", err)
 		return err
 	}
+	defer settleInfo.SwapService.Close()
+
+	if enableReporting, _ := req.Options[enableReportingKwd].(bool); enableReporting {
+		// reporting is optional, the daemon runs without it
+		reportingStore, err := chain.InitReportingStore(cctx.ConfigRoot, settleInfo)
+		if err != nil {
+			fmt.Println("init reporting store err: ", err)
+			log.Errorf("init reporting store err:%+v", err)
+		} else {
+			defer reportingStore.Close()
+		}
+	}
 
 	// init ip2location db
 	if err := bindata.Init(); err != nil {
@@ -291,7 +291,9 @@ func TestCommands(t *testing.T) {
 		"/p2p/handshake",
 		"/settlement",
 		"/settlement/list",
+		"/settlement/outbox",
 		"/settlement/peer",
+		"/settlement/query",
 		"/storage/upload/cheque",
 		"/test",
 		"/test/cheque",
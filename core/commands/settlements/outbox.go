@@ -0,0 +1,34 @@
+package settlement
+
+import (
+	"time"
+
+	cmds "github.com/TRON-US/go-btfs-cmds"
+	"github.com/bittorrent/go-btfs/chain"
+	"github.com/bittorrent/go-btfs/settlement/swap"
+)
+
+type outboxResponse struct {
+	Intents []swap.PaymentIntent `json:"intents"`
+	Len     int                  `json:"len"`
+}
+
+var OutboxSettlementCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List payments queued while the chain is unreachable.",
+		ShortDescription: `
+Payments which fail during a chain or price oracle outage are queued and
+issued automatically once connectivity returns.`,
+	},
+	RunTimeout: 5 * time.Minute,
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		intents := chain.SettleObject.SwapService.OutboxIntents()
+
+		rsp := outboxResponse{
+			Intents: intents,
+			Len:     len(intents),
+		}
+		return cmds.EmitOnce(res, &rsp)
+	},
+	Type: &outboxResponse{},
+}
@@ -0,0 +1,62 @@
+package settlement
+
+import (
+	"errors"
+	"time"
+
+	cmds "github.com/TRON-US/go-btfs-cmds"
+	"github.com/bittorrent/go-btfs/chain"
+	"github.com/bittorrent/go-btfs/settlement/swap/reporting"
+)
+
+const queryLimitOptionName = "limit"
+
+var QuerySettlementCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Run a read-only SQL query against the settlement reporting database.",
+		ShortDescription: `
+Requires the daemon to be started with --enable-settlement-reporting.
+Only a single SELECT statement is allowed. Available tables:
+
+  cheques(direction, vault, beneficiary, amount, amount_btt, time)
+  cashouts(tx_hash, vault, amount, amount_btt, status, time)
+
+direction is either 'sent' or 'received' and times are unix timestamps.
+amount is the exact amount in base units as a decimal string, amount_btt
+the same amount in BTT as a floating point number. Use amount_btt for
+SUM and other arithmetic, or CAST(amount AS REAL) for base units, since
+base unit sums overflow 64 bit integers.
+
+At most --limit rows are returned, the result is marked as truncated if
+the query matched more rows.
+
+The cheques table is rebuilt from the node's records when the daemon
+starts. The node drops cheque records after 180 days, so older cheques
+are not included. Cashouts are kept.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("sql", true, false, "The SELECT statement to run."),
+	},
+	Options: []cmds.Option{
+		cmds.IntOption(queryLimitOptionName, "l", "Maximum number of rows to return.").WithDefault(reporting.DefaultQueryLimit),
+	},
+	RunTimeout: 5 * time.Minute,
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		store := chain.SettleObject.ReportingStore
+		if store == nil {
+			return errors.New("settlement reporting is not enabled, start the daemon with --enable-settlement-reporting")
+		}
+
+		limit, _ := req.Options[queryLimitOptionName].(int)
+		if limit <= 0 {
+			return errors.New("limit must be positive")
+		}
+
+		result, err := store.Query(req.Context, req.Arguments[0], limit)
+		if err != nil {
+			return err
+		}
+		return cmds.EmitOnce(res, result)
+	},
+	Type: &reporting.QueryResult{},
+}
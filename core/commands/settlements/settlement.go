@@ -9,7 +9,9 @@ var SettlementCmd = &cmds.Command{
 		Tagline: "Interact with chequebook services on BTFS.",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"list": ListSettlementCmd,
-		"peer": PeerSettlementCmd,
+		"list":   ListSettlementCmd,
+		"peer":   PeerSettlementCmd,
+		"outbox": OutboxSettlementCmd,
+		"query":  QuerySettlementCmd,
 	},
 }
@@ -7,6 +7,8 @@ import (
 
 var (
 	ErrPeerNoSettlements = errors.New("no settlements for peer")
+	// ErrPaymentQueued is reported to accounting if a payment was queued until the chain is reachable again
+	ErrPaymentQueued = errors.New("payment queued")
 )
 
 // Interface is the interface used by Accounting to trigger settlement
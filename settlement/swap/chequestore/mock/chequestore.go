@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/bittorrent/go-btfs/settlement/swap/vault"
@@ -46,14 +47,42 @@ func (s *Service) ReceiveCheque(ctx context.Context, cheque *vault.SignedCheque,
 	return s.receiveCheque(ctx, cheque, exchangeRate)
 }
 
-func (s *Service) LastCheque(vault common.Address) (*vault.SignedCheque, error) {
+func (s *Service) LastReceivedCheque(vault common.Address) (*vault.SignedCheque, error) {
 	return s.lastCheque(vault)
 }
 
-func (s *Service) LastCheques() (map[common.Address]*vault.SignedCheque, error) {
+func (s *Service) LastReceivedCheques() (map[common.Address]*vault.SignedCheque, error) {
 	return s.lastCheques()
 }
 
+func (s *Service) ReceivedChequeRecordsByPeer(vault common.Address) ([]vault.ChequeRecord, error) {
+	return nil, errors.New("Error")
+}
+
+func (s *Service) ReceivedChequeRecordsAll() (map[common.Address][]vault.ChequeRecord, error) {
+	return nil, errors.New("Error")
+}
+
+func (s *Service) ReceivedStatsHistory(days int) ([]vault.DailyReceivedStats, error) {
+	return nil, errors.New("Error")
+}
+
+func (s *Service) SentStatsHistory(days int) ([]vault.DailySentStats, error) {
+	return nil, errors.New("Error")
+}
+
+func (s *Service) StoreSendChequeRecord(vault, beneficiary common.Address, amount *big.Int) error {
+	return nil
+}
+
+func (s *Service) SendChequeRecordsByPeer(beneficiary common.Address) ([]vault.ChequeRecord, error) {
+	return nil, errors.New("Error")
+}
+
+func (s *Service) SendChequeRecordsAll() (map[common.Address][]vault.ChequeRecord, error) {
+	return nil, errors.New("Error")
+}
+
 // Option is the option passed to the mock ChequeStore service
 type Option interface {
 	apply(*Service)
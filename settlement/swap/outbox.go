@@ -0,0 +1,266 @@
+package swap
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/bittorrent/go-btfs/settlement/swap/vault"
+	"github.com/bittorrent/go-btfs/transaction/storage"
+)
+
+const (
+	// DefaultOutboxCapacity is the default maximum number of queued payment intents.
+	DefaultOutboxCapacity = 256
+	// DefaultOutboxRetryInterval is the default interval for probing the chain while payments are queued.
+	DefaultOutboxRetryInterval = 30 * time.Second
+
+	outboxProbeTimeout = 10 * time.Second
+	outboxKey          = "swap_outbox_intents"
+)
+
+var (
+	// ErrOutboxFull is the error if a payment can not be queued because the outbox is full.
+	ErrOutboxFull = errors.New("payment outbox full")
+	// ErrOutboxClosed is the error if a payment can not be queued because the outbox has been closed.
+	ErrOutboxClosed = errors.New("payment outbox closed")
+)
+
+// ProbeFunc checks whether the chain and the price oracle are reachable.
+type ProbeFunc func(ctx context.Context) error
+
+// PaymentIntent is a payment which could not be issued during a chain outage.
+type PaymentIntent struct {
+	Peer       string    `json:"peer"`
+	Amount     *big.Int  `json:"amount"`
+	ContractId string    `json:"contract_id"`
+	QueuedAt   time.Time `json:"queued_at"`
+	LastError  string    `json:"last_error"`
+}
+
+// outbox holds payment intents until connectivity to the chain returns.
+// The intents are persisted in the state store, so they survive a restart.
+type outbox struct {
+	lock          sync.Mutex
+	store         storage.StateStorer
+	intents       []PaymentIntent
+	capacity      int
+	retryInterval time.Duration
+	probe         ProbeFunc
+	started       bool
+	flushing      bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newOutbox(store storage.StateStorer, capacity int, retryInterval time.Duration, probe ProbeFunc) (*outbox, error) {
+	intents := make([]PaymentIntent, 0)
+	err := store.Get(outboxKey, &intents)
+	if err != nil && err != storage.ErrNotFound {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &outbox{
+		store:         store,
+		intents:       intents,
+		capacity:      capacity,
+		retryInterval: retryInterval,
+		probe:         probe,
+		ctx:           ctx,
+		cancel:        cancel,
+	}, nil
+}
+
+// reachable reports whether the probe succeeds within outboxProbeTimeout.
+func (o *outbox) reachable() error {
+	ctx, cancel := context.WithTimeout(o.ctx, outboxProbeTimeout)
+	defer cancel()
+	return o.probe(ctx)
+}
+
+// push queues and persists the intent. It returns true if the flush loop
+// needs to be started.
+func (o *outbox) push(intent PaymentIntent) (bool, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.ctx.Err() != nil {
+		return false, ErrOutboxClosed
+	}
+	if len(o.intents) >= o.capacity {
+		return false, ErrOutboxFull
+	}
+
+	intents := append(o.intents[:len(o.intents):len(o.intents)], intent)
+	if err := o.store.Put(outboxKey, intents); err != nil {
+		return false, err
+	}
+	o.intents = intents
+
+	return o.startFlushing(), nil
+}
+
+// startFlushing marks the flush loop as running. It returns false if it
+// already is, or the outbox has not been started yet. The lock must be
+// held when called.
+func (o *outbox) startFlushing() bool {
+	if !o.started || o.flushing || len(o.intents) == 0 {
+		return false
+	}
+	o.flushing = true
+	o.wg.Add(1)
+	return true
+}
+
+// take removes and unpersists all intents and marks the flush loop as
+// stopped. Intents are removed before they are resubmitted, so a failure
+// to persist never leads to a payment being issued twice.
+func (o *outbox) take() ([]PaymentIntent, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if err := o.store.Put(outboxKey, []PaymentIntent{}); err != nil {
+		return nil, err
+	}
+	intents := o.intents
+	o.intents = make([]PaymentIntent, 0)
+	o.flushing = false
+	return intents, nil
+}
+
+func (o *outbox) list() []PaymentIntent {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	intents := make([]PaymentIntent, len(o.intents))
+	copy(intents, o.intents)
+	return intents
+}
+
+// close stops the flush loop. Queued intents stay persisted.
+func (o *outbox) close() {
+	o.lock.Lock()
+	o.cancel()
+	o.lock.Unlock()
+
+	o.wg.Wait()
+
+	if n := len(o.list()); n > 0 {
+		log.Warnf("swap: %d queued payments were not issued, they will be resubmitted after restart", n)
+	}
+}
+
+// SetOutbox enables queueing of payments which fail because the chain is unreachable.
+// Queued payments are persisted and resubmitted through accounting once probe succeeds again.
+// Nothing is resubmitted before StartOutbox is called.
+func (s *Service) SetOutbox(capacity int, retryInterval time.Duration, probe ProbeFunc) error {
+	o, err := newOutbox(s.store, capacity, retryInterval, probe)
+	if err != nil {
+		return err
+	}
+	s.outbox = o
+	return nil
+}
+
+// StartOutbox starts resubmitting queued payments, including the ones loaded
+// from the state store. It must be called once accounting can pay through this service.
+func (s *Service) StartOutbox() {
+	if s.outbox == nil {
+		return
+	}
+
+	s.outbox.lock.Lock()
+	s.outbox.started = true
+	start := s.outbox.startFlushing()
+	n := len(s.outbox.intents)
+	s.outbox.lock.Unlock()
+
+	if start {
+		log.Infof("swap: %d queued payments loaded", n)
+		go s.flushOutbox()
+	}
+}
+
+// OutboxIntents returns the payment intents currently waiting for the chain.
+func (s *Service) OutboxIntents() []PaymentIntent {
+	if s.outbox == nil {
+		return []PaymentIntent{}
+	}
+	return s.outbox.list()
+}
+
+// Close stops resubmitting queued payments.
+func (s *Service) Close() error {
+	if s.outbox != nil {
+		s.outbox.close()
+	}
+	return nil
+}
+
+// queuePayment queues the payment if payErr was caused by the chain or the
+// price oracle being unreachable before the cheque was sent.
+// It returns false if the payment should be reported as failed.
+func (s *Service) queuePayment(peer string, amount *big.Int, contractId string, payErr error) bool {
+	if s.outbox == nil || !errors.Is(payErr, vault.ErrChainUnavailable) {
+		return false
+	}
+
+	start, err := s.outbox.push(PaymentIntent{
+		Peer:       peer,
+		Amount:     new(big.Int).Set(amount),
+		ContractId: contractId,
+		QueuedAt:   time.Now(),
+		LastError:  payErr.Error(),
+	})
+	if err != nil {
+		log.Warnf("swap: could not queue payment to peer %v: %v", peer, err)
+		return false
+	}
+
+	log.Infof("swap: chain unreachable, queued payment of %d to peer %v", amount, peer)
+	if start {
+		go s.flushOutbox()
+	}
+	return true
+}
+
+// flushOutbox waits for the chain to become reachable and resubmits all
+// queued intents. Payments which fail again are queued by Pay and start
+// a new loop. It returns once the intents are resubmitted or the outbox is closed.
+func (s *Service) flushOutbox() {
+	defer s.outbox.wg.Done()
+
+	ticker := time.NewTicker(s.outbox.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.outbox.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.outbox.reachable(); err != nil {
+			log.Debugf("swap: chain still unreachable: %v", err)
+			continue
+		}
+
+		intents, err := s.outbox.take()
+		if err != nil {
+			log.Warnf("swap: could not remove queued payments from outbox: %v", err)
+			continue
+		}
+		for _, intent := range intents {
+			log.Infof("swap: resubmitting queued payment of %d to peer %v", intent.Amount, intent.Peer)
+			if err := s.accounting.Settle(intent.Peer, intent.Amount, intent.ContractId); err != nil {
+				log.Warnf("swap: resubmitting queued payment to peer %v: %v", intent.Peer, err)
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,249 @@
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bittorrent/go-btfs/settlement/swap/vault"
+	mockstore "github.com/bittorrent/go-btfs/statestore/mock"
+)
+
+type settleCall struct {
+	peer       string
+	amount     *big.Int
+	contractId string
+}
+
+type settleRecorder struct {
+	settleCalled chan settleCall
+}
+
+func (r *settleRecorder) Settle(peer string, amount *big.Int, contractId string) error {
+	r.settleCalled <- settleCall{peer: peer, amount: amount, contractId: contractId}
+	return nil
+}
+
+func (r *settleRecorder) NotifyPaymentReceived(peer string, amount *big.Int) error {
+	return nil
+}
+
+func (r *settleRecorder) NotifyPaymentSent(peer string, amount *big.Int, receivedError error) {
+}
+
+type testProbe struct {
+	lock sync.Mutex
+	err  error
+}
+
+func (p *testProbe) set(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.err = err
+}
+
+func (p *testProbe) probe(ctx context.Context) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.err
+}
+
+func testIntent(peer string) PaymentIntent {
+	return PaymentIntent{
+		Peer:       peer,
+		Amount:     big.NewInt(10),
+		ContractId: "contract",
+		QueuedAt:   time.Now(),
+	}
+}
+
+func TestOutboxPush(t *testing.T) {
+	store := mockstore.NewStateStore()
+	o, err := newOutbox(store, 2, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start, err := o.push(testIntent("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start {
+		t.Fatal("expected push not to start the flush loop before the outbox is started")
+	}
+
+	o.lock.Lock()
+	o.started = true
+	start = o.startFlushing()
+	o.lock.Unlock()
+	if !start {
+		t.Fatal("expected start to start the flush loop")
+	}
+
+	start, err = o.push(testIntent("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start {
+		t.Fatal("expected second push not to start another flush loop")
+	}
+
+	_, err = o.push(testIntent("c"))
+	if !errors.Is(err, ErrOutboxFull) {
+		t.Fatalf("wrong error. wanted %v, got %v", ErrOutboxFull, err)
+	}
+
+	// intents are reloaded from the store
+	reloaded, err := newOutbox(store, 2, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intents := reloaded.list()
+	if len(intents) != 2 || intents[0].Peer != "a" || intents[1].Peer != "b" {
+		t.Fatalf("wrong reloaded intents %v", intents)
+	}
+
+	intents, err = o.take()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intents) != 2 {
+		t.Fatalf("got %d intents, wanted 2", len(intents))
+	}
+	if len(o.list()) != 0 {
+		t.Fatal("expected outbox to be empty")
+	}
+
+	reloaded, err = newOutbox(store, 2, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.list()) != 0 {
+		t.Fatal("expected taken intents to be removed from the store")
+	}
+
+	// the outbox accepts intents again once the loop is marked as stopped
+	start, err = o.push(testIntent("d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !start {
+		t.Fatal("expected push after take to start the flush loop")
+	}
+}
+
+func TestOutboxFlush(t *testing.T) {
+	store := mockstore.NewStateStore()
+	recorder := &settleRecorder{settleCalled: make(chan settleCall, 1)}
+	s := &Service{
+		store:      store,
+		accounting: recorder,
+	}
+
+	probe := &testProbe{err: errors.New("unreachable")}
+	err := s.SetOutbox(2, 10*time.Millisecond, probe.probe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.StartOutbox()
+	defer s.Close()
+
+	if s.queuePayment("peer", big.NewInt(10), "contract", errors.New("reject")) {
+		t.Fatal("queued a payment which did not fail because of the chain")
+	}
+
+	payErr := fmt.Errorf("%w: unreachable", vault.ErrChainUnavailable)
+	if !s.queuePayment("peer", big.NewInt(10), "contract", payErr) {
+		t.Fatal("expected payment to be queued")
+	}
+
+	select {
+	case call := <-recorder.settleCalled:
+		t.Fatalf("resubmitted payment %v while the chain is unreachable", call)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	probe.set(nil)
+
+	select {
+	case call := <-recorder.settleCalled:
+		if call.peer != "peer" || call.amount.Cmp(big.NewInt(10)) != 0 || call.contractId != "contract" {
+			t.Fatalf("wrong resubmitted payment %v", call)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected queued payment to be resubmitted")
+	}
+
+	if len(s.OutboxIntents()) != 0 {
+		t.Fatal("expected outbox to be empty")
+	}
+}
+
+func TestOutboxClose(t *testing.T) {
+	store := mockstore.NewStateStore()
+	recorder := &settleRecorder{settleCalled: make(chan settleCall, 1)}
+	s := &Service{
+		store:      store,
+		accounting: recorder,
+	}
+
+	probe := &testProbe{err: errors.New("unreachable")}
+	err := s.SetOutbox(2, 10*time.Millisecond, probe.probe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.StartOutbox()
+
+	payErr := fmt.Errorf("%w: unreachable", vault.ErrChainUnavailable)
+	if !s.queuePayment("peer", big.NewInt(10), "contract", payErr) {
+		t.Fatal("expected payment to be queued")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("close did not stop the flush loop")
+	}
+
+	if s.queuePayment("peer", big.NewInt(10), "contract", payErr) {
+		t.Fatal("queued a payment after close")
+	}
+
+	// the queued payment is resubmitted after a restart
+	probe.set(nil)
+	restarted := &Service{
+		store:      store,
+		accounting: recorder,
+	}
+	err = restarted.SetOutbox(2, 10*time.Millisecond, probe.probe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+
+	select {
+	case call := <-recorder.settleCalled:
+		t.Fatalf("resubmitted payment %v before the outbox was started", call)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	restarted.StartOutbox()
+
+	select {
+	case call := <-recorder.settleCalled:
+		if call.peer != "peer" || call.contractId != "contract" {
+			t.Fatalf("wrong resubmitted payment %v", call)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected persisted payment to be resubmitted")
+	}
+}
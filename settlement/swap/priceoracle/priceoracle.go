@@ -3,6 +3,7 @@ package priceoracle
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 
 	conabi "github.com/bittorrent/go-btfs/chain/abi"
@@ -12,6 +13,9 @@ import (
 )
 
 var (
+	// ErrUnavailable is the error when the price oracle contract could not be called
+	ErrUnavailable = errors.New("price oracle unavailable")
+
 	errDecodeABI = errors.New("could not decode abi data")
 )
 
@@ -41,12 +45,12 @@ func New(priceOracleAddress common.Address, transactionService transaction.Servi
 }
 
 func (s *service) GetPrice(ctx context.Context) (*big.Int, error) {
-	price, err := s.CurrentPrice()
+	price, err := s.currentPrice(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	rate, err := s.CurrentRate()
+	rate, err := s.currentRate(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -55,16 +59,20 @@ func (s *service) GetPrice(ctx context.Context) (*big.Int, error) {
 }
 
 func (s *service) CurrentRate() (*big.Int, error) {
+	return s.currentRate(context.Background())
+}
+
+func (s *service) currentRate(ctx context.Context) (*big.Int, error) {
 	callData, err := priceOracleABI.Pack("getExchangeRate")
 	if err != nil {
 		return nil, err
 	}
-	result, err := s.transactionService.Call(context.Background(), &transaction.TxRequest{
+	result, err := s.transactionService.Call(ctx, &transaction.TxRequest{
 		To:   &s.priceOracleAddress,
 		Data: callData,
 	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 
 	results, err := priceOracleABI.Unpack("getExchangeRate", result)
@@ -85,16 +93,20 @@ func (s *service) CurrentRate() (*big.Int, error) {
 }
 
 func (s *service) CurrentPrice() (*big.Int, error) {
+	return s.currentPrice(context.Background())
+}
+
+func (s *service) currentPrice(ctx context.Context) (*big.Int, error) {
 	callData, err := priceOracleABI.Pack("getPrice")
 	if err != nil {
 		return nil, err
 	}
-	result, err := s.transactionService.Call(context.Background(), &transaction.TxRequest{
+	result, err := s.transactionService.Call(ctx, &transaction.TxRequest{
 		To:   &s.priceOracleAddress,
 		Data: callData,
 	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 
 	results, err := priceOracleABI.Unpack("getPrice", result)
@@ -0,0 +1,7 @@
+package reporting
+
+import "database/sql"
+
+func (s *Store) ReadOnlyDB() *sql.DB {
+	return s.readOnlyDB
+}
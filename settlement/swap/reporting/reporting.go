@@ -0,0 +1,355 @@
+// Package reporting mirrors cheque and cashout records into an embedded
+// SQLite database, so they can be analysed with ad-hoc SQL queries.
+package reporting
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/bittorrent/go-btfs/settlement/swap/vault"
+	logging "github.com/ipfs/go-log"
+	_ "modernc.org/sqlite"
+)
+
+var log = logging.Logger("reporting")
+
+const (
+	// DirectionReceived marks cheques received from peers.
+	DirectionReceived = "received"
+	// DirectionSent marks cheques sent to peers.
+	DirectionSent = "sent"
+
+	// DefaultQueryLimit is the default maximum number of rows returned by Query.
+	DefaultQueryLimit = 1000
+
+	// size of the queue between the payment path and the writer
+	queueSize = 1024
+
+	chequesTable = `
+CREATE TABLE IF NOT EXISTS cheques (
+	direction   TEXT    NOT NULL,
+	vault       TEXT    NOT NULL,
+	beneficiary TEXT    NOT NULL,
+	amount      TEXT    NOT NULL,
+	amount_btt  REAL    NOT NULL,
+	time        INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS cheques_time ON cheques (time);`
+
+	cashoutsTable = `
+CREATE TABLE IF NOT EXISTS cashouts (
+	tx_hash    TEXT    NOT NULL PRIMARY KEY,
+	vault      TEXT    NOT NULL,
+	amount     TEXT    NOT NULL,
+	amount_btt REAL    NOT NULL,
+	status     TEXT    NOT NULL,
+	time       INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS cashouts_time ON cashouts (time);`
+
+	insertChequeQuery  = "INSERT INTO cheques (direction, vault, beneficiary, amount, amount_btt, time) VALUES (?, ?, ?, ?, ?, ?)"
+	insertCashoutQuery = "INSERT OR IGNORE INTO cashouts (tx_hash, vault, amount, amount_btt, status, time) VALUES (?, ?, ?, ?, ?, ?)"
+)
+
+var (
+	// ErrNotReadOnly is the error if a query is not a single SELECT statement.
+	ErrNotReadOnly = errors.New("only a single SELECT statement is allowed")
+
+	// 1 BTT in the base unit of cheque amounts
+	bttUnit = new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+)
+
+// QueryResult is the result of an ad-hoc query.
+type QueryResult struct {
+	Columns   []string        `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`
+	Truncated bool            `json:"truncated"` // more rows than the limit matched
+}
+
+type statement struct {
+	query string
+	args  []interface{}
+}
+
+// Store is the SQLite reporting database. It implements vault.Reporter.
+type Store struct {
+	db         *sql.DB // used by the writer only
+	readOnlyDB *sql.DB // used for ad-hoc queries
+	queue      chan statement
+	quit       chan struct{}
+	wg         sync.WaitGroup
+	closeOnce  sync.Once
+}
+
+var _ vault.Reporter = (*Store)(nil)
+
+// Open opens or creates the reporting database at path and starts the writer.
+func Open(path string) (*Store, error) {
+	if err := registerNoAttach(); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path))
+	if err != nil {
+		return nil, fmt.Errorf("open reporting db: %w", err)
+	}
+	// sqlite allows only one writer at a time
+	db.SetMaxOpenConns(1)
+
+	if _, err = db.Exec(chequesTable + cashoutsTable); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create reporting schema: %w", err)
+	}
+
+	readOnlyDB, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro&_pragma=busy_timeout(5000)", path))
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open read-only reporting db: %w", err)
+	}
+
+	s := &Store{
+		db:         db,
+		readOnlyDB: readOnlyDB,
+		queue:      make(chan statement, queueSize),
+		quit:       make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.writer()
+
+	return s, nil
+}
+
+// writer executes queued statements until the store is closed.
+func (s *Store) writer() {
+	defer s.wg.Done()
+	for {
+		select {
+		case stmt := <-s.queue:
+			s.exec(stmt)
+		case <-s.quit:
+			// write out what is still queued
+			for {
+				select {
+				case stmt := <-s.queue:
+					s.exec(stmt)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Store) exec(stmt statement) {
+	if _, err := s.db.Exec(stmt.query, stmt.args...); err != nil {
+		log.Warnf("reporting: write record: %v", err)
+	}
+}
+
+// enqueue hands the statement to the writer without blocking the caller.
+func (s *Store) enqueue(stmt statement) {
+	select {
+	case <-s.quit:
+		return
+	default:
+	}
+
+	select {
+	case s.queue <- stmt:
+	default:
+		log.Warnf("reporting: queue full, dropping record")
+	}
+}
+
+func chequeStatement(direction string, record vault.ChequeRecord) statement {
+	return statement{
+		query: insertChequeQuery,
+		args: []interface{}{
+			direction,
+			record.Vault.String(),
+			record.Beneficiary.String(),
+			amountString(record.Amount),
+			amountBTT(record.Amount),
+			record.ReceiveTime,
+		},
+	}
+}
+
+func cashoutStatement(result vault.CashOutResult) statement {
+	return statement{
+		query: insertCashoutQuery,
+		args: []interface{}{
+			result.TxHash.String(),
+			result.Vault.String(),
+			amountString(result.Amount),
+			amountBTT(result.Amount),
+			result.Status,
+			result.CashTime,
+		},
+	}
+}
+
+// ReportReceivedCheque queues a received cheque record.
+func (s *Store) ReportReceivedCheque(record vault.ChequeRecord) {
+	s.enqueue(chequeStatement(DirectionReceived, record))
+}
+
+// ReportSentCheque queues a sent cheque record.
+func (s *Store) ReportSentCheque(record vault.ChequeRecord) {
+	s.enqueue(chequeStatement(DirectionSent, record))
+}
+
+// ReportCashout queues a cashout result.
+func (s *Store) ReportCashout(result vault.CashOutResult) {
+	s.enqueue(cashoutStatement(result))
+}
+
+// Rebuild synchronously replaces all cheque records and adds the cashout
+// results which are not in the database yet, in a single transaction.
+// The node only keeps the last cashout per vault, so cashouts which are
+// already mirrored are never removed.
+func (s *Store) Rebuild(received, sent []vault.ChequeRecord, cashouts []vault.CashOutResult) error {
+	stmts := make([]statement, 0, len(received)+len(sent)+len(cashouts))
+	for _, record := range received {
+		stmts = append(stmts, chequeStatement(DirectionReceived, record))
+	}
+	for _, record := range sent {
+		stmts = append(stmts, chequeStatement(DirectionSent, record))
+	}
+	for _, result := range cashouts {
+		stmts = append(stmts, cashoutStatement(result))
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	// recreate the table, so an older schema is replaced as well
+	if _, err := tx.Exec("DROP TABLE IF EXISTS cheques;" + chequesTable); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Query runs a read-only statement against the database and returns at
+// most limit rows.
+func (s *Store) Query(ctx context.Context, query string, limit int) (*QueryResult, error) {
+	query, err := checkReadOnly(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.readOnlyDB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{
+		Columns: columns,
+		Rows:    make([][]interface{}, 0),
+	}
+	for rows.Next() {
+		if len(result.Rows) >= limit {
+			result.Truncated = true
+			break
+		}
+
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			// text columns may be returned as raw bytes
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Close stops the writer after flushing queued records and closes the database.
+func (s *Store) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.quit)
+		s.wg.Wait()
+		err = s.db.Close()
+		if rerr := s.readOnlyDB.Close(); err == nil {
+			err = rerr
+		}
+	})
+	return err
+}
+
+// checkReadOnly returns the trimmed query if it is a single statement which
+// starts with SELECT or WITH. Data modifying statements in a WITH clause are
+// rejected by the read-only connection, and ATTACH is disabled for all
+// connections, so the query can not write to any file.
+func checkReadOnly(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	query = strings.TrimSpace(strings.TrimSuffix(query, ";"))
+
+	single, err := singleStatement(query)
+	if err != nil {
+		return "", err
+	}
+	if !single {
+		return "", ErrNotReadOnly
+	}
+
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "", ErrNotReadOnly
+	}
+	switch strings.ToLower(fields[0]) {
+	case "select", "with":
+		return query, nil
+	default:
+		return "", ErrNotReadOnly
+	}
+}
+
+func amountString(amount *big.Int) string {
+	if amount == nil {
+		return "0"
+	}
+	return amount.String()
+}
+
+// amountBTT converts an amount in base units into BTT.
+func amountBTT(amount *big.Int) float64 {
+	if amount == nil {
+		return 0
+	}
+	btt, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), bttUnit).Float64()
+	return btt
+}
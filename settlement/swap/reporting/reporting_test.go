@@ -0,0 +1,243 @@
+package reporting_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bittorrent/go-btfs/settlement/swap/reporting"
+	"github.com/bittorrent/go-btfs/settlement/swap/vault"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func newTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "reporting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return dir
+}
+
+// btt returns n BTT in base units.
+func btt(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+}
+
+func testRecord(amount *big.Int) vault.ChequeRecord {
+	return vault.ChequeRecord{
+		Vault:       common.HexToAddress("0xab"),
+		Beneficiary: common.HexToAddress("0xcd"),
+		Amount:      amount,
+		ReceiveTime: 100,
+	}
+}
+
+func TestQuery(t *testing.T) {
+	path := filepath.Join(newTestDir(t), "reporting.db")
+	store, err := reporting.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := testRecord(btt(10))
+	err = store.Rebuild([]vault.ChequeRecord{record}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.ReportSentCheque(record)
+	store.ReportSentCheque(record)
+
+	// closing waits for queued records to be written
+	err = store.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = reporting.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	result, err := store.Query(context.Background(), "SELECT direction, COUNT(*), SUM(amount_btt) FROM cheques GROUP BY direction ORDER BY direction;", reporting.DefaultQueryLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Columns) != 3 {
+		t.Fatalf("got %d columns, wanted 3", len(result.Columns))
+	}
+	if len(result.Rows) != 2 {
+		t.Fatalf("got %d rows, wanted 2", len(result.Rows))
+	}
+	if result.Rows[0][0] != reporting.DirectionReceived || result.Rows[0][1] != int64(1) || result.Rows[0][2] != float64(10) {
+		t.Fatalf("wrong received row %v", result.Rows[0])
+	}
+	if result.Rows[1][0] != reporting.DirectionSent || result.Rows[1][1] != int64(2) || result.Rows[1][2] != float64(20) {
+		t.Fatalf("wrong sent row %v", result.Rows[1])
+	}
+	if result.Truncated {
+		t.Fatal("result should not be truncated")
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	store, err := reporting.Open(filepath.Join(newTestDir(t), "reporting.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	cashout := vault.CashOutResult{
+		TxHash:   common.HexToHash("0x01"),
+		Vault:    common.HexToAddress("0xab"),
+		Amount:   btt(10),
+		CashTime: 200,
+		Status:   "success",
+	}
+
+	// a rebuild replaces the cheques but keeps the cashouts
+	err = store.Rebuild([]vault.ChequeRecord{testRecord(btt(1)), testRecord(btt(2))}, nil, []vault.CashOutResult{cashout})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cashout.TxHash = common.HexToHash("0x02")
+	err = store.Rebuild([]vault.ChequeRecord{testRecord(btt(1))}, nil, []vault.CashOutResult{cashout})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.Query(context.Background(), "SELECT (SELECT COUNT(*) FROM cheques), (SELECT COUNT(*) FROM cashouts)", reporting.DefaultQueryLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rows[0][0] != int64(1) || result.Rows[0][1] != int64(2) {
+		t.Fatalf("got %v cheques and %v cashouts, wanted 1 and 2", result.Rows[0][0], result.Rows[0][1])
+	}
+}
+
+func TestQueryLargeAmounts(t *testing.T) {
+	store, err := reporting.Open(filepath.Join(newTestDir(t), "reporting.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// the sum of the amounts in base units overflows a 64 bit integer
+	err = store.Rebuild([]vault.ChequeRecord{testRecord(btt(10)), testRecord(btt(10))}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.Query(context.Background(), "SELECT SUM(amount_btt), SUM(CAST(amount AS REAL)) FROM cheques", reporting.DefaultQueryLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rows[0][0] != float64(20) || result.Rows[0][1] != float64(20e18) {
+		t.Fatalf("wrong sums %v", result.Rows[0])
+	}
+}
+
+func TestQueryLimit(t *testing.T) {
+	store, err := reporting.Open(filepath.Join(newTestDir(t), "reporting.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	records := []vault.ChequeRecord{testRecord(btt(1)), testRecord(btt(2)), testRecord(btt(3))}
+	err = store.Rebuild(records, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.Query(context.Background(), "SELECT * FROM cheques", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 2 || !result.Truncated {
+		t.Fatalf("got %d rows, truncated %v, wanted 2 rows and truncated", len(result.Rows), result.Truncated)
+	}
+
+	result, err = store.Query(context.Background(), "SELECT * FROM cheques", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 3 || result.Truncated {
+		t.Fatalf("got %d rows, truncated %v, wanted 3 rows and not truncated", len(result.Rows), result.Truncated)
+	}
+}
+
+func TestQueryReadOnly(t *testing.T) {
+	store, err := reporting.Open(filepath.Join(newTestDir(t), "reporting.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	err = store.Rebuild([]vault.ChequeRecord{testRecord(btt(1))}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := newTestDir(t)
+	attached := filepath.Join(dir, "attached.db")
+
+	for _, query := range []string{
+		"",
+		";",
+		"DELETE FROM cheques",
+		"DROP TABLE cheques",
+		"PRAGMA query_only = 0",
+		"SELECT 1; DELETE FROM cheques",
+		"SELECT 1; ATTACH 'file:" + attached + "?mode=rwc' AS x; CREATE TABLE x.p(a)",
+		"SELECT 1 /* ; */; SELECT 2",
+	} {
+		_, err := store.Query(context.Background(), query, reporting.DefaultQueryLimit)
+		if !errors.Is(err, reporting.ErrNotReadOnly) {
+			t.Fatalf("query %q: wanted %v, got %v", query, reporting.ErrNotReadOnly, err)
+		}
+	}
+
+	// semicolons in literals, identifiers and comments are fine
+	for _, query := range []string{
+		"SELECT * FROM cheques WHERE beneficiary LIKE '%;%';",
+		`SELECT 1 AS "a;b" -- ;`,
+		"SELECT 1 /* ; */",
+	} {
+		_, err = store.Query(context.Background(), query, reporting.DefaultQueryLimit)
+		if err != nil {
+			t.Fatalf("query %q: %v", query, err)
+		}
+	}
+
+	// writes which pass the statement check are rejected by the read-only connection
+	_, err = store.Query(context.Background(), "WITH c AS (SELECT 1) DELETE FROM cheques", reporting.DefaultQueryLimit)
+	if err == nil {
+		t.Fatal("expected the read-only connection to reject the delete")
+	}
+
+	// attaching another database is disabled on the connection itself
+	rows, err := store.ReadOnlyDB().Query("ATTACH 'file:" + attached + "?mode=rwc' AS x")
+	if err == nil {
+		rows.Close()
+		t.Fatal("expected attach to fail")
+	}
+	if _, err := os.Stat(attached); !os.IsNotExist(err) {
+		t.Fatalf("attach created %s", attached)
+	}
+
+	result, err := store.Query(context.Background(), "SELECT COUNT(*) FROM cheques", reporting.DefaultQueryLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rows[0][0] != int64(1) {
+		t.Fatalf("got %v cheques, wanted 1", result.Rows[0][0])
+	}
+}
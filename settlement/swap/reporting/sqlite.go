@@ -0,0 +1,68 @@
+package reporting
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"modernc.org/libc"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var registerOnce sync.Once
+
+// registerNoAttach makes sqlite forbid ATTACH on every connection opened
+// afterwards. Ad-hoc queries can then not create or write any other file,
+// whatever the statement check lets through.
+func registerNoAttach() error {
+	var err error
+	registerOnce.Do(func() {
+		tls := libc.NewTLS()
+		defer tls.Close()
+
+		// same way the driver passes Go callbacks to sqlite
+		xInit := *(*uintptr)(unsafe.Pointer(&struct {
+			f func(*libc.TLS, uintptr, uintptr, uintptr) int32
+		}{noAttach}))
+		if rc := sqlite3.Xsqlite3_auto_extension(tls, xInit); rc != sqlite3.SQLITE_OK {
+			err = errors.New("register sqlite connection limits failed")
+		}
+	})
+	return err
+}
+
+// noAttach is run by sqlite for each new connection.
+func noAttach(tls *libc.TLS, db, pzErrMsg, pThunk uintptr) int32 {
+	sqlite3.Xsqlite3_limit(tls, db, sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+	return sqlite3.SQLITE_OK
+}
+
+// singleStatement reports whether query holds at most one statement, not
+// counting one trailing semicolon. Statement ends are found by the sqlite
+// tokenizer, so semicolons in literals, identifiers and comments are fine.
+func singleStatement(query string) (bool, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+
+	tls := libc.NewTLS()
+	defer tls.Close()
+
+	for i := strings.IndexByte(query, ';'); i >= 0; {
+		zSQL, err := libc.CString(query[:i+1])
+		if err != nil {
+			return false, err
+		}
+		complete := sqlite3.Xsqlite3_complete(tls, zSQL)
+		libc.Xfree(tls, zSQL)
+		if complete != 0 {
+			return false, nil
+		}
+
+		next := strings.IndexByte(query[i+1:], ';')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return true, nil
+}
@@ -70,6 +70,7 @@ type Service struct {
 	cashout     vault.CashoutService
 	addressbook Addressbook
 	chainID     int64
+	outbox      *outbox
 }
 
 // New creates a new swap Service.
@@ -169,6 +170,9 @@ func (s *Service) Pay(ctx context.Context, peer string, amount *big.Int, contrac
 	balance, err := s.proto.EmitCheque(ctx, peer, amount, contractId, s.vault.Issue)
 
 	if err != nil {
+		if s.queuePayment(peer, amount, contractId, err) {
+			err = settlement.ErrPaymentQueued
+		}
 		return
 	}
 
@@ -3,11 +3,14 @@ package swap_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/bittorrent/go-btfs/settlement"
 	"github.com/bittorrent/go-btfs/settlement/swap"
 	mockchequestore "github.com/bittorrent/go-btfs/settlement/swap/chequestore/mock"
 	"github.com/bittorrent/go-btfs/settlement/swap/swapprotocol"
@@ -122,8 +125,10 @@ func (m *addressbookMock) PutVault(peer string, vault common.Address) error {
 }
 
 type cashoutMock struct {
-	cashCheque    func(ctx context.Context, vault, recipient common.Address) (common.Hash, error)
-	cashoutStatus func(ctx context.Context, vaultAddress common.Address) (*vault.CashoutStatus, error)
+	cashCheque       func(ctx context.Context, vault, recipient common.Address) (common.Hash, error)
+	cashoutStatus    func(ctx context.Context, vaultAddress common.Address) (*vault.CashoutStatus, error)
+	hasCashoutAction func(ctx context.Context, peer common.Address) (bool, error)
+	cashoutResults   func() ([]vault.CashOutResult, error)
 }
 
 func (m *cashoutMock) CashCheque(ctx context.Context, vault, recipient common.Address) (common.Hash, error) {
@@ -132,6 +137,12 @@ func (m *cashoutMock) CashCheque(ctx context.Context, vault, recipient common.Ad
 func (m *cashoutMock) CashoutStatus(ctx context.Context, vaultAddress common.Address) (*vault.CashoutStatus, error) {
 	return m.cashoutStatus(ctx, vaultAddress)
 }
+func (m *cashoutMock) HasCashoutAction(ctx context.Context, peer common.Address) (bool, error) {
+	return m.hasCashoutAction(ctx, peer)
+}
+func (m *cashoutMock) CashoutResults() ([]vault.CashOutResult, error) {
+	return m.cashoutResults()
+}
 
 func TestReceiveCheque(t *testing.T) {
 	store := mockstore.NewStateStore()
@@ -354,7 +365,7 @@ func TestPay(t *testing.T) {
 	var emitCalled bool
 	swap := swap.New(
 		&swapProtocolMock{
-			emitCheque: func(ctx context.Context, p string, a *big.Int, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
+			emitCheque: func(ctx context.Context, p string, a *big.Int, contractId string, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
 				//if !peer.Equal(p) {
 				if strings.Compare(peer, p) != 0 {
 					t.Fatal("sending to wrong peer")
@@ -408,7 +419,7 @@ func TestPayIssueError(t *testing.T) {
 
 	swap := swap.New(
 		&swapProtocolMock{
-			emitCheque: func(c context.Context, a1 string, i *big.Int, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
+			emitCheque: func(c context.Context, a1 string, i *big.Int, contractId string, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
 				return nil, errReject
 			},
 		},
@@ -442,6 +453,89 @@ func TestPayIssueError(t *testing.T) {
 
 }
 
+func TestPayQueuedDuringOutage(t *testing.T) {
+	store := mockstore.NewStateStore()
+
+	amount := big.NewInt(50)
+	peer := peerInfo.ID("abcd").String()
+	errUnreachable := fmt.Errorf("%w: unreachable", vault.ErrChainUnavailable)
+	errReject := errors.New("reject")
+	networkID := int64(1)
+
+	var lock sync.Mutex
+	emitErr := errUnreachable
+	swapService := swap.New(
+		&swapProtocolMock{
+			emitCheque: func(c context.Context, a1 string, i *big.Int, contractId string, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				return nil, emitErr
+			},
+		},
+		store,
+		mockvault.NewVault(),
+		mockchequestore.NewChequeStore(),
+		&addressbookMock{},
+		networkID,
+		&cashoutMock{},
+		nil,
+	)
+
+	observer := newTestObserver()
+	swapService.SetAccounting(observer)
+
+	err := swapService.SetOutbox(1, time.Hour, func(ctx context.Context) error {
+		return errors.New("unreachable")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer swapService.Close()
+
+	swapService.Pay(context.Background(), peer, amount, "contract")
+	select {
+	case call := <-observer.sentCalled:
+		if !errors.Is(call.err, settlement.ErrPaymentQueued) {
+			t.Fatalf("wrong error. wanted %v, got %v", settlement.ErrPaymentQueued, call.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected observer to be called")
+	}
+
+	intents := swapService.OutboxIntents()
+	if len(intents) != 1 {
+		t.Fatalf("got %d queued intents, wanted 1", len(intents))
+	}
+	if intents[0].Peer != peer || intents[0].Amount.Cmp(amount) != 0 || intents[0].ContractId != "contract" {
+		t.Fatalf("wrong queued intent %v", intents[0])
+	}
+
+	// the outbox is full, so the next payment fails
+	swapService.Pay(context.Background(), peer, amount, "contract")
+	select {
+	case call := <-observer.sentCalled:
+		if !errors.Is(call.err, errUnreachable) {
+			t.Fatalf("wrong error. wanted %v, got %v", errUnreachable, call.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected observer to be called")
+	}
+
+	// errors which do not come from the chain are never queued
+	lock.Lock()
+	emitErr = errReject
+	lock.Unlock()
+	swapService.Pay(context.Background(), peer, amount, "contract")
+	select {
+	case call := <-observer.sentCalled:
+		if !errors.Is(call.err, errReject) {
+			t.Fatalf("wrong error. wanted %v, got %v", errReject, call.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected observer to be called")
+	}
+}
+
 func TestPayUnknownBeneficiary(t *testing.T) {
 	store := mockstore.NewStateStore()
 
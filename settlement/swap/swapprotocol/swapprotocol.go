@@ -200,6 +200,9 @@ func (s *Service) EmitCheque(ctx context.Context, peer string, amount *big.Int,
 
 		price, err := s.priceOracle.GetPrice(ctx)
 		if err != nil {
+			if errors.Is(err, priceoracle.ErrUnavailable) {
+				return fmt.Errorf("%w: %v", vault.ErrChainUnavailable, err)
+			}
 			return err
 		}
 
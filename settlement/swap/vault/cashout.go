@@ -265,6 +265,8 @@ func (s *cashoutService) storeCashResult(ctx context.Context, vault common.Addre
 	err = s.store.Put(statestore.CashoutResultKey(vault), &cashResult)
 	if err != nil {
 		log.Infof("CashOutStats:put cashoutResultKey err:%+v", err)
+	} else {
+		getReporter().ReportCashout(cashResult)
 	}
 	return nil
 }
@@ -320,6 +320,7 @@ func (s *chequeStore) storeChequeRecord(vault common.Address, amount *big.Int) e
 		return err
 	}
 
+	getReporter().ReportReceivedCheque(chequeRecord)
 	return nil
 }
 func (s *chequeStore) ReceivedStatsHistory(days int) ([]DailyReceivedStats, error) {
@@ -569,6 +570,7 @@ func (s *chequeStore) StoreSendChequeRecord(vault, beneficiary common.Address, a
 		return err
 	}
 
+	getReporter().ReportSentCheque(chequeRecord)
 	return nil
 }
 
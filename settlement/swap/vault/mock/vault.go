@@ -115,6 +115,34 @@ func (s *Service) TotalIssuedCount() (ti int, err error) {
 	return 0, errors.New("Error")
 }
 
+func (s *Service) TotalIssued() (ti *big.Int, err error) {
+	return big.NewInt(0), nil
+}
+
+func (s *Service) TotalReceivedCount() (ti int, err error) {
+	return 0, nil
+}
+
+func (s *Service) TotalReceivedCashedCount() (ti int, err error) {
+	return 0, nil
+}
+
+func (s *Service) TotalReceived() (ti *big.Int, err error) {
+	return big.NewInt(0), nil
+}
+
+func (s *Service) TotalReceivedCashed() (ti *big.Int, err error) {
+	return big.NewInt(0), nil
+}
+
+func (s *Service) TotalDailyReceived() (ti *big.Int, err error) {
+	return big.NewInt(0), nil
+}
+
+func (s *Service) TotalDailyReceivedCashed() (ti *big.Int, err error) {
+	return big.NewInt(0), nil
+}
+
 func (s *Service) TotalPaidOut(ctx context.Context) (ti *big.Int, err error) {
 	return big.NewInt(0), errors.New("Error")
 }
@@ -0,0 +1,43 @@
+package vault
+
+import (
+	"sync"
+)
+
+// Reporter receives a copy of every cheque and cashout record which is stored.
+// Implementations must not block, they are called on the payment path.
+type Reporter interface {
+	// ReportReceivedCheque is called when a received cheque record is stored.
+	ReportReceivedCheque(record ChequeRecord)
+	// ReportSentCheque is called when a sent cheque record is stored.
+	ReportSentCheque(record ChequeRecord)
+	// ReportCashout is called when a cashout result is stored.
+	ReportCashout(result CashOutResult)
+}
+
+type noopReporter struct{}
+
+func (noopReporter) ReportReceivedCheque(record ChequeRecord) {}
+func (noopReporter) ReportSentCheque(record ChequeRecord)     {}
+func (noopReporter) ReportCashout(result CashOutResult)       {}
+
+var (
+	reporterLock sync.RWMutex
+	reporter     Reporter = noopReporter{}
+)
+
+// SetReporter sets the reporter which mirrors cheque and cashout records.
+func SetReporter(r Reporter) {
+	reporterLock.Lock()
+	defer reporterLock.Unlock()
+	if r == nil {
+		r = noopReporter{}
+	}
+	reporter = r
+}
+
+func getReporter() Reporter {
+	reporterLock.RLock()
+	defer reporterLock.RUnlock()
+	return reporter
+}
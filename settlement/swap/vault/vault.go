@@ -31,6 +31,8 @@ var (
 	ErrOutOfFunds = errors.New("vault out of funds")
 	// ErrInsufficientFunds is the error when the vault has not enough free funds for a user action
 	ErrInsufficientFunds = errors.New("insufficient token balance")
+	// ErrChainUnavailable is the error when a call to the chain or the price oracle failed before a cheque was sent
+	ErrChainUnavailable = errors.New("chain unavailable")
 
 	vaultABI               = transaction.ParseABIUnchecked(conabi.VaultABI)
 	chequeCashedEventType  = vaultABI.Events["ChequeCashed"]
@@ -166,12 +168,12 @@ func (s *service) AvailableBalance(ctx context.Context) (*big.Int, error) {
 
 	balance, err := s.TotalBalance(ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrChainUnavailable, err)
 	}
 
 	totalPaidOut, err := s.contract.TotalPaidOut(ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrChainUnavailable, err)
 	}
 
 	// balance plus totalPaidOut is the total amount ever put into the vault (ignoring deposits and withdrawals which cancelled out)